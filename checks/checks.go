@@ -0,0 +1,54 @@
+// Package checks defines the pluggable probe interface used to build up
+// the Result served at /healthz. Each built-in probe (HTTP, TCP, TLS, DNS,
+// exec, file-freshness, systemd) implements Check and is composed by a
+// Registry; operators can add more probes via CHECKS_CONFIG without a
+// recompile.
+package checks
+
+import (
+	"context"
+	"time"
+)
+
+// CheckDetail is the per-check outcome surfaced in the /healthz JSON body.
+// The Consecutive* and StateSince fields are only populated for checks
+// wrapped in a Hysteresis.
+type CheckDetail struct {
+	OK                   bool       `json:"ok"`
+	Warning              bool       `json:"warning,omitempty"`
+	Message              string     `json:"message,omitempty"`
+	ConsecutiveFailures  int        `json:"consecutive_failures,omitempty"`
+	ConsecutiveSuccesses int        `json:"consecutive_successes,omitempty"`
+	StateSince           *time.Time `json:"state_since,omitempty"`
+}
+
+// Check is a single probe that can be registered with a Registry. Run must
+// respect ctx cancellation/deadline and return promptly.
+type Check interface {
+	Name() string
+	Run(ctx context.Context) CheckDetail
+}
+
+// ok/fail are small helpers used by built-in checks to build a CheckDetail.
+func ok(msg string) CheckDetail   { return CheckDetail{OK: true, Message: msg} }
+func fail(msg string) CheckDetail { return CheckDetail{OK: false, Message: msg} }
+
+// withTimeout runs fn with ctx bounded by timeout, returning a CheckDetail
+// produced by fn or a timeout failure if fn does not return in time.
+func withTimeout(ctx context.Context, timeout time.Duration, fn func(context.Context) CheckDetail) CheckDetail {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resCh := make(chan CheckDetail, 1)
+	go func() { resCh <- fn(ctx) }()
+
+	select {
+	case res := <-resCh:
+		return res
+	case <-ctx.Done():
+		return fail("timeout")
+	}
+}
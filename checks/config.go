@@ -0,0 +1,229 @@
+package checks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the goss-style resource file loaded from CHECKS_CONFIG: each
+// top-level key names a probe kind, and its value is a map of check name
+// to that probe's stanza. YAML and JSON are both accepted: LoadConfig
+// parses a path ending in ".json" as JSON and anything else as YAML.
+type Config struct {
+	HTTP    map[string]HTTPStanza    `yaml:"http" json:"http"`
+	TCP     map[string]TCPStanza     `yaml:"tcp" json:"tcp"`
+	TLS     map[string]TLSStanza     `yaml:"tls" json:"tls"`
+	DNS     map[string]DNSStanza     `yaml:"dns" json:"dns"`
+	Exec    map[string]ExecStanza    `yaml:"exec" json:"exec"`
+	File    map[string]FileStanza    `yaml:"file" json:"file"`
+	Systemd map[string]SystemdStanza `yaml:"systemd" json:"systemd"`
+}
+
+// HysteresisStanza is embedded in every check stanza to configure its
+// retry policy and rise/fall debouncing, modeled on goss's
+// --retry-timeout/--sleep. All fields are optional; a zero value disables
+// retries and hysteresis (Rise/Fall default to 1, i.e. flip immediately).
+type HysteresisStanza struct {
+	Retries      int    `yaml:"retries" json:"retries"`
+	Sleep        string `yaml:"sleep" json:"sleep"`
+	RetryTimeout string `yaml:"retry_timeout" json:"retry_timeout"`
+	Rise         int    `yaml:"rise" json:"rise"`
+	Fall         int    `yaml:"fall" json:"fall"`
+}
+
+// policy builds the RetryPolicy and rise/fall values this stanza declares.
+func (h HysteresisStanza) policy() (RetryPolicy, int, int) {
+	return RetryPolicy{
+		Retries:      h.Retries,
+		Sleep:        parseDuration(h.Sleep, 0),
+		RetryTimeout: parseDuration(h.RetryTimeout, 0),
+	}, h.Rise, h.Fall
+}
+
+// HTTPStanza configures an HTTPCheck.
+type HTTPStanza struct {
+	URL        string `yaml:"url" json:"url"`
+	Status     int    `yaml:"status" json:"status"`
+	BodyRegexp string `yaml:"body_regexp" json:"body_regexp"`
+	Insecure   bool   `yaml:"insecure" json:"insecure"`
+	Timeout    string `yaml:"timeout" json:"timeout"`
+	Critical   *bool  `yaml:"critical" json:"critical"`
+
+	HysteresisStanza `yaml:",inline"`
+}
+
+// TCPStanza configures a TCPCheck.
+type TCPStanza struct {
+	Addr     string `yaml:"addr" json:"addr"`
+	Timeout  string `yaml:"timeout" json:"timeout"`
+	Critical *bool  `yaml:"critical" json:"critical"`
+
+	HysteresisStanza `yaml:",inline"`
+}
+
+// TLSStanza configures a TLSCertCheck.
+type TLSStanza struct {
+	Addr     string `yaml:"addr" json:"addr"`
+	WarnDays int    `yaml:"warn_days" json:"warn_days"`
+	WarnOnly bool   `yaml:"warn_only" json:"warn_only"`
+	Timeout  string `yaml:"timeout" json:"timeout"`
+	Critical *bool  `yaml:"critical" json:"critical"`
+
+	HysteresisStanza `yaml:",inline"`
+}
+
+// DNSStanza configures a DNSCheck.
+type DNSStanza struct {
+	Host     string `yaml:"host" json:"host"`
+	Timeout  string `yaml:"timeout" json:"timeout"`
+	Critical *bool  `yaml:"critical" json:"critical"`
+
+	HysteresisStanza `yaml:",inline"`
+}
+
+// ExecStanza configures an ExecCheck.
+type ExecStanza struct {
+	Command  []string `yaml:"command" json:"command"`
+	Timeout  string   `yaml:"timeout" json:"timeout"`
+	Critical *bool    `yaml:"critical" json:"critical"`
+
+	HysteresisStanza `yaml:",inline"`
+}
+
+// FileStanza configures a FileCheck.
+type FileStanza struct {
+	Path     string `yaml:"path" json:"path"`
+	MaxAge   string `yaml:"max_age" json:"max_age"`
+	Critical *bool  `yaml:"critical" json:"critical"`
+
+	HysteresisStanza `yaml:",inline"`
+}
+
+// SystemdStanza configures a SystemdCheck.
+type SystemdStanza struct {
+	Unit     string `yaml:"unit" json:"unit"`
+	Timeout  string `yaml:"timeout" json:"timeout"`
+	Critical *bool  `yaml:"critical" json:"critical"`
+
+	HysteresisStanza `yaml:",inline"`
+}
+
+// isCritical defaults a stanza's critical flag to true, matching the
+// pre-config behavior where every check could take /healthz down.
+func isCritical(c *bool) bool {
+	if c == nil {
+		return true
+	}
+	return *c
+}
+
+// parseDuration parses an optional duration stanza field, returning def
+// when s is empty and falling back to def on a malformed value.
+func parseDuration(s string, def time.Duration) time.Duration {
+	if s == "" {
+		return def
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+// LoadConfig reads a goss-style YAML or JSON resource file from path and
+// returns a Registry with every declared check registered.
+func LoadConfig(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read checks config: %w", err)
+	}
+
+	var cfg Config
+	if strings.HasSuffix(path, ".json") {
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("parse checks config as JSON: %w", err)
+		}
+	} else {
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("parse checks config as YAML: %w", err)
+		}
+	}
+
+	reg := NewRegistry()
+
+	for name, s := range cfg.HTTP {
+		c := &HTTPCheck{
+			CheckName:  name,
+			URL:        s.URL,
+			Status:     s.Status,
+			BodyRegexp: s.BodyRegexp,
+			Insecure:   s.Insecure,
+			Timeout:    parseDuration(s.Timeout, 5*time.Second),
+		}
+		retry, rise, fall := s.policy()
+		reg.Register(NewHysteresis(c, retry, rise, fall), isCritical(s.Critical))
+	}
+	for name, s := range cfg.TCP {
+		c := &TCPCheck{
+			CheckName: name,
+			Addr:      s.Addr,
+			Timeout:   parseDuration(s.Timeout, 3*time.Second),
+		}
+		retry, rise, fall := s.policy()
+		reg.Register(NewHysteresis(c, retry, rise, fall), isCritical(s.Critical))
+	}
+	for name, s := range cfg.TLS {
+		c := &TLSCertCheck{
+			CheckName: name,
+			Addr:      s.Addr,
+			WarnDays:  s.WarnDays,
+			WarnOnly:  s.WarnOnly,
+			Timeout:   parseDuration(s.Timeout, 5*time.Second),
+		}
+		retry, rise, fall := s.policy()
+		reg.Register(NewHysteresis(c, retry, rise, fall), isCritical(s.Critical))
+	}
+	for name, s := range cfg.DNS {
+		c := &DNSCheck{
+			CheckName: name,
+			Host:      s.Host,
+			Timeout:   parseDuration(s.Timeout, 3*time.Second),
+		}
+		retry, rise, fall := s.policy()
+		reg.Register(NewHysteresis(c, retry, rise, fall), isCritical(s.Critical))
+	}
+	for name, s := range cfg.Exec {
+		c := &ExecCheck{
+			CheckName: name,
+			Command:   s.Command,
+			Timeout:   parseDuration(s.Timeout, 5*time.Second),
+		}
+		retry, rise, fall := s.policy()
+		reg.Register(NewHysteresis(c, retry, rise, fall), isCritical(s.Critical))
+	}
+	for name, s := range cfg.File {
+		c := &FileCheck{
+			CheckName: name,
+			Path:      s.Path,
+			MaxAge:    parseDuration(s.MaxAge, 2*time.Minute),
+		}
+		retry, rise, fall := s.policy()
+		reg.Register(NewHysteresis(c, retry, rise, fall), isCritical(s.Critical))
+	}
+	for name, s := range cfg.Systemd {
+		c := &SystemdCheck{
+			CheckName: name,
+			Unit:      s.Unit,
+			Timeout:   parseDuration(s.Timeout, time.Second),
+		}
+		retry, rise, fall := s.policy()
+		reg.Register(NewHysteresis(c, retry, rise, fall), isCritical(s.Critical))
+	}
+
+	return reg, nil
+}
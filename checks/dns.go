@@ -0,0 +1,35 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// DNSCheck reports healthy when Host resolves to at least one address.
+type DNSCheck struct {
+	CheckName string
+	Host      string
+	Timeout   time.Duration
+}
+
+// Name implements Check.
+func (c *DNSCheck) Name() string { return c.CheckName }
+
+// Run implements Check.
+func (c *DNSCheck) Run(ctx context.Context) CheckDetail {
+	return withTimeout(ctx, c.Timeout, c.run)
+}
+
+func (c *DNSCheck) run(ctx context.Context) CheckDetail {
+	var r net.Resolver
+	addrs, err := r.LookupHost(ctx, c.Host)
+	if err != nil {
+		return fail("lookup failed: " + err.Error())
+	}
+	if len(addrs) == 0 {
+		return fail("no addresses returned")
+	}
+	return ok(fmt.Sprintf("resolved to %s", addrs[0]))
+}
@@ -0,0 +1,50 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// ExecCheck reports healthy when Command exits 0. Command[0] is the
+// binary; the remainder are its arguments.
+type ExecCheck struct {
+	CheckName string
+	Command   []string
+	Timeout   time.Duration
+}
+
+// Name implements Check.
+func (c *ExecCheck) Name() string { return c.CheckName }
+
+// Run implements Check.
+func (c *ExecCheck) Run(ctx context.Context) CheckDetail {
+	if len(c.Command) == 0 {
+		return fail("no command configured")
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, c.Command[0], c.Command[1:]...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fail("command timeout")
+		}
+		return fail(fmt.Sprintf("exit error: %v: %s", err, trimOutput(out)))
+	}
+	return ok("exit 0")
+}
+
+func trimOutput(out []byte) string {
+	const max = 200
+	if len(out) > max {
+		return string(out[:max]) + "...(truncated)"
+	}
+	return string(out)
+}
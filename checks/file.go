@@ -0,0 +1,41 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// FileCheck reports healthy when Path exists, is not a directory, and (if
+// MaxAge is positive) was modified within MaxAge. This is the original
+// health-file probe pangLB shipped with.
+type FileCheck struct {
+	CheckName string
+	Path      string
+	MaxAge    time.Duration
+}
+
+// Name implements Check.
+func (c *FileCheck) Name() string { return c.CheckName }
+
+// Run implements Check.
+func (c *FileCheck) Run(_ context.Context) CheckDetail {
+	fi, err := os.Stat(c.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fail("health file missing")
+		}
+		return fail("stat error: " + err.Error())
+	}
+	if fi.IsDir() {
+		return fail("health file path is a directory")
+	}
+	if c.MaxAge > 0 {
+		age := time.Since(fi.ModTime())
+		if age > c.MaxAge {
+			return fail(fmt.Sprintf("health file too old: %s > %s", age.Round(time.Second), c.MaxAge))
+		}
+	}
+	return ok("present")
+}
@@ -0,0 +1,87 @@
+package checks
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// HTTPCheck probes a URL with GET and reports healthy when the
+// response status matches Status (0 means "any 2xx") and, if BodyRegexp is
+// set, the response body matches it.
+type HTTPCheck struct {
+	CheckName  string
+	URL        string
+	Status     int
+	BodyRegexp string
+	Insecure   bool
+	Timeout    time.Duration
+
+	initOnce sync.Once
+	client   *http.Client
+	re       *regexp.Regexp
+	reErr    error
+}
+
+// Name implements Check.
+func (c *HTTPCheck) Name() string { return c.CheckName }
+
+// init builds the HTTP client and compiles BodyRegexp once, guarded by
+// initOnce so concurrent Run calls (e.g. overlapping /healthz requests)
+// never race on the lazily-created fields.
+func (c *HTTPCheck) init() {
+	c.client = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: c.Insecure}, //nolint:gosec
+		},
+	}
+	if c.BodyRegexp != "" {
+		c.re, c.reErr = regexp.Compile(c.BodyRegexp)
+	}
+}
+
+// Run implements Check.
+func (c *HTTPCheck) Run(ctx context.Context) CheckDetail {
+	return withTimeout(ctx, c.Timeout, c.run)
+}
+
+func (c *HTTPCheck) run(ctx context.Context) CheckDetail {
+	c.initOnce.Do(c.init)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return fail("bad request: " + err.Error())
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fail("request failed: " + err.Error())
+	}
+	defer resp.Body.Close()
+
+	if c.Status != 0 && resp.StatusCode != c.Status {
+		return fail(fmt.Sprintf("status %d, want %d", resp.StatusCode, c.Status))
+	}
+	if c.Status == 0 && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+		return fail(fmt.Sprintf("status %d", resp.StatusCode))
+	}
+
+	if c.BodyRegexp == "" {
+		return ok(fmt.Sprintf("status %d", resp.StatusCode))
+	}
+	if c.reErr != nil {
+		return fail("invalid body regexp: " + c.reErr.Error())
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fail("read body: " + err.Error())
+	}
+	if !c.re.Match(body) {
+		return fail("body did not match " + c.BodyRegexp)
+	}
+	return ok(fmt.Sprintf("status %d, body matched", resp.StatusCode))
+}
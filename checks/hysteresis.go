@@ -0,0 +1,127 @@
+package checks
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how many times a Check's Run is retried before its
+// outcome is fed into a Hysteresis, modeled on goss's
+// --retry-timeout/--sleep: keep retrying on failure, sleeping Sleep
+// between attempts, until either Retries retries (Retries+1 attempts in
+// all) are used up or RetryTimeout elapses.
+type RetryPolicy struct {
+	Retries      int
+	Sleep        time.Duration
+	RetryTimeout time.Duration
+}
+
+// Hysteresis wraps a Check with a RetryPolicy and rise/fall debouncing, so
+// a single transient failure (a stat blip, a systemd restart, a
+// health-file replaced-in-place race) doesn't flip /healthz to 503: the
+// wrapped state must fail Fall times in a row before reporting unhealthy,
+// and succeed Rise times in a row before reporting healthy again.
+type Hysteresis struct {
+	Check Check
+	Retry RetryPolicy
+	Rise  int
+	Fall  int
+
+	mu                   sync.Mutex
+	initialized          bool
+	healthy              bool
+	consecutiveSuccesses int
+	consecutiveFailures  int
+	stateSince           time.Time
+}
+
+// NewHysteresis wraps c with retry and rise/fall settings, defaulting
+// Rise/Fall to 1 (i.e. no debouncing) when not set.
+func NewHysteresis(c Check, retry RetryPolicy, rise, fall int) *Hysteresis {
+	if rise <= 0 {
+		rise = 1
+	}
+	if fall <= 0 {
+		fall = 1
+	}
+	return &Hysteresis{Check: c, Retry: retry, Rise: rise, Fall: fall}
+}
+
+// Name implements Check.
+func (h *Hysteresis) Name() string { return h.Check.Name() }
+
+// Run implements Check: it retries the wrapped Check per h.Retry, then
+// advances the rise/fall counters and reports the debounced state.
+func (h *Hysteresis) Run(ctx context.Context) CheckDetail {
+	detail := h.runWithRetry(ctx)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.initialized {
+		h.initialized = true
+		h.healthy = detail.OK
+		h.stateSince = time.Now()
+	}
+
+	if detail.OK {
+		h.consecutiveSuccesses++
+		h.consecutiveFailures = 0
+		if !h.healthy && h.consecutiveSuccesses >= h.Rise {
+			h.healthy = true
+			h.stateSince = time.Now()
+		}
+	} else {
+		h.consecutiveFailures++
+		h.consecutiveSuccesses = 0
+		if h.healthy && h.consecutiveFailures >= h.Fall {
+			h.healthy = false
+			h.stateSince = time.Now()
+		}
+	}
+
+	stateSince := h.stateSince
+	detail.OK = h.healthy
+	detail.ConsecutiveFailures = h.consecutiveFailures
+	detail.ConsecutiveSuccesses = h.consecutiveSuccesses
+	detail.StateSince = &stateSince
+	return detail
+}
+
+// runWithRetry runs the wrapped Check, retrying on failure per h.Retry,
+// and returns the last (or first successful) CheckDetail.
+func (h *Hysteresis) runWithRetry(ctx context.Context) CheckDetail {
+	var deadline time.Time
+	if h.Retry.RetryTimeout > 0 {
+		deadline = time.Now().Add(h.Retry.RetryTimeout)
+	}
+
+	attempts := h.Retry.Retries + 1
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var last CheckDetail
+	for attempt := 0; attempt < attempts; attempt++ {
+		last = h.Check.Run(ctx)
+		if last.OK {
+			return last
+		}
+		if attempt == attempts-1 {
+			break
+		}
+		if !deadline.IsZero() && time.Now().Add(h.Retry.Sleep).After(deadline) {
+			break
+		}
+		if h.Retry.Sleep <= 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return last
+		case <-time.After(h.Retry.Sleep):
+		}
+	}
+	return last
+}
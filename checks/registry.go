@@ -0,0 +1,99 @@
+package checks
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// entry pairs a registered Check with whether its failure should flip the
+// overall result to unhealthy (critical) or merely degrade it to warning.
+type entry struct {
+	check    Check
+	critical bool
+}
+
+// Observer receives the outcome of each Check as Run executes it, letting
+// callers (e.g. Prometheus instrumentation) hook in without Registry
+// depending on any particular metrics backend.
+type Observer interface {
+	Observe(name string, critical bool, detail CheckDetail, duration time.Duration)
+}
+
+// Registry composes a set of Checks into a single aggregated Result. The
+// zero value is an empty Registry ready for use, which is what NewRegistry
+// returns.
+type Registry struct {
+	mu       sync.RWMutex
+	entries  []entry
+	Observer Observer
+}
+
+// NewRegistry returns an empty Registry ready for Register calls.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds c to the registry. When critical is false, a failing c
+// degrades the aggregated Result to Warning instead of OK=false.
+func (r *Registry) Register(c Check, critical bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry{check: c, critical: critical})
+}
+
+// Result is the aggregated outcome of running every registered Check.
+type Result struct {
+	OK      bool
+	Warning bool
+	Details map[string]CheckDetail
+}
+
+// Run executes every registered Check concurrently and composes their
+// CheckDetails into a Result. A failing critical check sets OK=false; a
+// failing non-critical check sets Warning=true but leaves OK untouched.
+func (r *Registry) Run(ctx context.Context) Result {
+	r.mu.RLock()
+	entries := make([]entry, len(r.entries))
+	copy(entries, r.entries)
+	r.mu.RUnlock()
+
+	type namedDetail struct {
+		name     string
+		detail   CheckDetail
+		critical bool
+	}
+
+	results := make([]namedDetail, len(entries))
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e entry) {
+			defer wg.Done()
+			start := time.Now()
+			detail := e.check.Run(ctx)
+			duration := time.Since(start)
+			results[i] = namedDetail{name: e.check.Name(), detail: detail, critical: e.critical}
+			if r.Observer != nil {
+				r.Observer.Observe(e.check.Name(), e.critical, detail, duration)
+			}
+		}(i, e)
+	}
+	wg.Wait()
+
+	res := Result{OK: true, Details: make(map[string]CheckDetail, len(results))}
+	for _, nd := range results {
+		res.Details[nd.name] = nd.detail
+		if !nd.detail.OK {
+			if nd.critical {
+				res.OK = false
+			} else {
+				res.Warning = true
+			}
+		}
+		if nd.detail.Warning {
+			res.Warning = true
+		}
+	}
+	return res
+}
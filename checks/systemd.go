@@ -0,0 +1,39 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"time"
+)
+
+// SystemdCheck reports healthy when `systemctl is-active --quiet Unit`
+// succeeds. Declare one SystemdCheck per unit to probe multiple units.
+type SystemdCheck struct {
+	CheckName string
+	Unit      string
+	Timeout   time.Duration
+}
+
+// Name implements Check.
+func (c *SystemdCheck) Name() string { return c.CheckName }
+
+// Run implements Check.
+func (c *SystemdCheck) Run(ctx context.Context) CheckDetail {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "systemctl", "is-active", "--quiet", c.Unit)
+	err := cmd.Run()
+	if err == nil {
+		return ok("active")
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fail("systemctl timeout")
+	}
+	return fail("not active")
+}
@@ -0,0 +1,35 @@
+package checks
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// TCPCheck reports healthy when a TCP connection to Addr succeeds.
+type TCPCheck struct {
+	CheckName string
+	Addr      string
+	Timeout   time.Duration
+}
+
+// Name implements Check.
+func (c *TCPCheck) Name() string { return c.CheckName }
+
+// Run implements Check.
+func (c *TCPCheck) Run(ctx context.Context) CheckDetail {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	var d net.Dialer
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := d.DialContext(ctx, "tcp", c.Addr)
+	if err != nil {
+		return fail("dial failed: " + err.Error())
+	}
+	_ = conn.Close()
+	return ok("connected")
+}
@@ -0,0 +1,70 @@
+package checks
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+)
+
+// TLSCertCheck connects to Addr, performs a TLS handshake, and warns (or
+// fails, if WarnOnly is false) when the leaf certificate expires within
+// WarnDays of its NotAfter.
+type TLSCertCheck struct {
+	CheckName string
+	Addr      string
+	WarnDays  int
+	WarnOnly  bool
+	Timeout   time.Duration
+}
+
+// Name implements Check.
+func (c *TLSCertCheck) Name() string { return c.CheckName }
+
+// Run implements Check.
+func (c *TLSCertCheck) Run(ctx context.Context) CheckDetail {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	var d net.Dialer
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	rawConn, err := d.DialContext(ctx, "tcp", c.Addr)
+	if err != nil {
+		return fail("dial failed: " + err.Error())
+	}
+	defer rawConn.Close()
+
+	host, _, err := net.SplitHostPort(c.Addr)
+	if err != nil {
+		host = c.Addr
+	}
+	conn := tls.Client(rawConn, &tls.Config{ServerName: host})
+	if err := conn.HandshakeContext(ctx); err != nil {
+		return fail("handshake failed: " + err.Error())
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return fail("no peer certificates presented")
+	}
+	leaf := certs[0]
+	remaining := time.Until(leaf.NotAfter)
+	warnAt := time.Duration(c.WarnDays) * 24 * time.Hour
+
+	if remaining <= 0 {
+		return fail(fmt.Sprintf("certificate expired %s ago", (-remaining).Round(time.Hour)))
+	}
+	if c.WarnDays > 0 && remaining <= warnAt {
+		msg := fmt.Sprintf("certificate expires in %s (< %d days)", remaining.Round(time.Hour), c.WarnDays)
+		if c.WarnOnly {
+			return CheckDetail{OK: true, Warning: true, Message: msg}
+		}
+		return fail(msg)
+	}
+	return ok(fmt.Sprintf("certificate valid for %s", remaining.Round(time.Hour)))
+}
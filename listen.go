@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+
+	"github.com/coreos/go-systemd/activation"
+)
+
+// listen returns a net.Listener for addr, preferring a systemd
+// socket-activated fd over a fresh net.Listen call. When LISTEN_FDS is set
+// (Type=notify service with ListenStream= sockets), the inherited fd(s)
+// are used instead of binding a new socket, so the listening socket
+// survives a SIGHUP re-exec without ever closing.
+func listen(addr string) (net.Listener, error) {
+	listeners, err := activation.Listeners()
+	if err != nil {
+		return nil, fmt.Errorf("systemd activation: %w", err)
+	}
+
+	switch len(listeners) {
+	case 0:
+		return net.Listen("tcp", addr)
+	case 1:
+		log.Printf("using socket-activated listener on %s", listeners[0].Addr())
+		return listeners[0], nil
+	default:
+		if ln := matchListener(listeners, addr); ln != nil {
+			log.Printf("using socket-activated listener on %s (matched LISTEN_ADDR)", ln.Addr())
+			return ln, nil
+		}
+		log.Printf("no socket-activated listener matched LISTEN_ADDR=%s, using first of %d", addr, len(listeners))
+		return listeners[0], nil
+	}
+}
+
+// matchListener picks the inherited listener whose port matches addr, or
+// nil if none match.
+func matchListener(listeners []net.Listener, addr string) net.Listener {
+	_, wantPort, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil
+	}
+	for _, ln := range listeners {
+		tcpAddr, ok := ln.Addr().(*net.TCPAddr)
+		if !ok {
+			continue
+		}
+		if strconv.Itoa(tcpAddr.Port) == wantPort {
+			return ln
+		}
+	}
+	return nil
+}
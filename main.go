@@ -2,31 +2,29 @@ package main
 
 import (
 	"context"
-	"crypto/tls"
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
-	"os/exec"
 	"os/signal"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
+
+	"pangLB/checks"
 )
 
+// Result is the JSON body served at /healthz: the aggregated outcome of
+// every registered check, plus an overall OK/Warning rollup.
 type Result struct {
-	OK     bool                   `json:"ok"`
-	Now    time.Time              `json:"now"`
-	Checks map[string]CheckDetail `json:"checks"`
-}
-
-type CheckDetail struct {
-	OK      bool   `json:"ok"`
-	Message string `json:"message,omitempty"`
+	OK      bool                          `json:"ok"`
+	Warning bool                          `json:"warning,omitempty"`
+	Now     time.Time                     `json:"now"`
+	Checks  map[string]checks.CheckDetail `json:"checks"`
 }
 
 func getenv(k, d string) string {
@@ -56,87 +54,101 @@ func parseBoolEnv(k string, d bool) bool {
 	return v == "1" || v == "true" || v == "yes" || v == "on"
 }
 
-func checkHealthFile(path string, maxAge time.Duration) (bool, string) {
-	fi, err := os.Stat(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, "health file missing"
-		}
-		return false, "stat error: " + err.Error()
-	}
-	if fi.IsDir() {
-		return false, "health file path is a directory"
-	}
-	if maxAge > 0 {
-		age := time.Since(fi.ModTime())
-		if age > maxAge {
-			return false, fmt.Sprintf("health file too old: %s > %s", age.Round(time.Second), maxAge)
-		}
-	}
-	return true, "present"
+// bearerTokenMatches reports whether r carries an "Authorization: Bearer
+// <token>" header equal to token, compared in constant time to avoid a
+// timing side channel on admin endpoints (METRICS_TOKEN, DRAIN_TOKEN).
+func bearerTokenMatches(r *http.Request, token string) bool {
+	got := r.Header.Get("Authorization")
+	want := "Bearer " + token
+	return len(got) == len(want) && subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
 }
 
-func checkSystemd(unit string, timeout time.Duration) (bool, string) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+// buildRegistry loads checks from CHECKS_CONFIG if set, otherwise falls
+// back to the legacy NEWT_HEALTH_FILE/CHECK_SYSTEMD env vars so existing
+// deployments keep working unchanged.
+func buildRegistry() *checks.Registry {
+	if cfgPath := os.Getenv("CHECKS_CONFIG"); cfgPath != "" {
+		reg, err := checks.LoadConfig(cfgPath)
+		if err != nil {
+			log.Fatalf("load CHECKS_CONFIG: %v", err)
+		}
+		return reg
+	}
 
-	// is-active --quiet returns exit code 0 if active
-	cmd := exec.CommandContext(ctx, "systemctl", "is-active", "--quiet", unit)
-	err := cmd.Run()
-	if err == nil {
-		return true, "active"
+	reg := checks.NewRegistry()
+	retry := checks.RetryPolicy{
+		Retries:      parseIntEnv("HEALTH_RETRIES", 0),
+		Sleep:        parseDurationEnv("HEALTH_RETRY_SLEEP", 0),
+		RetryTimeout: parseDurationEnv("HEALTH_RETRY_TIMEOUT", 0),
 	}
+	rise := parseIntEnv("HEALTH_RISE", 0)
+	fall := parseIntEnv("HEALTH_FALL", 0)
 
-	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-		return false, "systemctl timeout"
+	healthFile := getenv("NEWT_HEALTH_FILE", "/tmp/newt-healthy")
+	maxAge := parseDurationEnv("MAX_AGE", 2*time.Minute)
+	reg.Register(checks.NewHysteresis(&checks.FileCheck{
+		CheckName: "newt_health_file",
+		Path:      healthFile,
+		MaxAge:    maxAge,
+	}, retry, rise, fall), true)
+
+	if parseBoolEnv("CHECK_SYSTEMD", false) {
+		reg.Register(checks.NewHysteresis(&checks.SystemdCheck{
+			CheckName: "systemd",
+			Unit:      getenv("SYSTEMD_UNIT", "newt"),
+			Timeout:   parseDurationEnv("SYSTEMD_TIMEOUT", 1*time.Second),
+		}, retry, rise, fall), true)
 	}
 
-	// Non-zero exit => not active (or systemd absent)
-	return false, "not active"
+	return reg
 }
 
 func main() {
+	maybeAdoptReexecListener()
+
 	listenAddr := getenv("LISTEN_ADDR", ":8443")
-	healthFile := getenv("NEWT_HEALTH_FILE", "/tmp/newt-healthy")
-	maxAge := parseDurationEnv("MAX_AGE", 2*time.Minute)
 
 	// TLS (recommended if Cloudflare monitor is HTTPS)
 	certFile := os.Getenv("TLS_CERT_FILE")
 	keyFile := os.Getenv("TLS_KEY_FILE")
 
-	// Optional systemd check
-	enableSystemd := parseBoolEnv("CHECK_SYSTEMD", false)
-	systemdUnit := getenv("SYSTEMD_UNIT", "newt")
-	systemdTimeout := parseDurationEnv("SYSTEMD_TIMEOUT", 1*time.Second)
+	// TLS_CLIENT_CA_FILE only has teeth when the server actually speaks TLS;
+	// without cert/key it would silently fall through to plain HTTP below,
+	// discarding the configured client-cert requirement.
+	if os.Getenv("TLS_CLIENT_CA_FILE") != "" && (certFile == "" || keyFile == "") {
+		log.Fatal("TLS_CLIENT_CA_FILE set but TLS_CERT_FILE/TLS_KEY_FILE missing: mTLS requires the server to terminate TLS")
+	}
 
-	mux := http.NewServeMux()
+	registry := buildRegistry()
+	registry.Observer = promObserver{}
 
-	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
-		res := Result{
-			Now:    time.Now().UTC(),
-			Checks: map[string]CheckDetail{},
-		}
+	pool := buildPool()
+	if pool != nil {
+		startPool(context.Background(), pool)
+		registry.Register(pool.Check("upstreams"), true)
+	}
 
-		okFile, msgFile := checkHealthFile(healthFile, maxAge)
-		res.Checks["newt_health_file"] = CheckDetail{OK: okFile, Message: msgFile}
+	mux := http.NewServeMux()
 
-		overall := okFile
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		run := registry.Run(r.Context())
 
-		if enableSystemd {
-			okSys, msgSys := checkSystemd(systemdUnit, systemdTimeout)
-			res.Checks["systemd"] = CheckDetail{OK: okSys, Message: msgSys}
-			overall = overall && okSys
+		res := Result{
+			OK:      run.OK,
+			Warning: run.Warning,
+			Now:     time.Now().UTC(),
+			Checks:  run.Details,
 		}
 
-		res.OK = overall
-
-		// Cloudflare monitor: treat 2xx as healthy, 503 as unhealthy
-		if overall {
-			w.WriteHeader(http.StatusOK)
-		} else {
-			w.WriteHeader(http.StatusServiceUnavailable)
+		// Cloudflare monitor: treat 2xx as healthy, 503 as unhealthy. A
+		// non-critical (warning) failure still returns 200 by design.
+		code := http.StatusOK
+		if !res.OK {
+			code = http.StatusServiceUnavailable
 		}
+		observeHealthzRequest(code)
 		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
 		_ = json.NewEncoder(w).Encode(res)
 	})
 
@@ -145,34 +157,52 @@ func main() {
 		_, _ = w.Write([]byte("ok\n"))
 	})
 
+	if pool != nil {
+		mux.HandleFunc("/upstreams", upstreamsHandler(pool))
+		mux.HandleFunc("/upstreams/", upstreamsDrainHandler(pool))
+	}
+
+	metricsSrv := registerMetrics(mux)
+
 	srv := &http.Server{
 		Addr:              listenAddr,
 		Handler:           mux,
 		ReadHeaderTimeout: 3 * time.Second,
 		IdleTimeout:       30 * time.Second,
-		TLSConfig: &tls.Config{
-			MinVersion: tls.VersionTLS12,
-		},
+		TLSConfig:         buildTLSConfig(certFile, keyFile),
 	}
 
-	ln, err := net.Listen("tcp", listenAddr)
+	ln, err := listen(listenAddr)
 	if err != nil {
 		log.Fatalf("listen failed: %v", err)
 	}
 
-	log.Printf("health server listening on %s (health file: %s, maxAge: %s)", listenAddr, healthFile, maxAge)
+	log.Printf("health server listening on %s", listenAddr)
 
-	// Graceful shutdown
+	// Graceful shutdown on SIGINT/SIGTERM; zero-downtime re-exec on SIGHUP.
 	stop := make(chan os.Signal, 2)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	reload := make(chan os.Signal, 2)
+	signal.Notify(reload, syscall.SIGHUP)
+	shutdownDone := make(chan struct{})
 	go func() {
-		<-stop
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		_ = srv.Shutdown(ctx)
+		defer close(shutdownDone)
+		for {
+			select {
+			case <-stop:
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				_ = srv.Shutdown(ctx)
+				cancel()
+				return
+			case <-reload:
+				reexec(srv, ln, metricsSrv)
+				return
+			}
+		}
 	}()
 
 	// Serve HTTP or HTTPS depending on cert env vars
+	var serveErr error
 	if certFile != "" && keyFile != "" {
 		if _, err := os.Stat(certFile); err != nil {
 			log.Fatalf("cert file invalid: %v", err)
@@ -180,10 +210,23 @@ func main() {
 		if _, err := os.Stat(keyFile); err != nil {
 			log.Fatalf("key file invalid: %v", err)
 		}
-		log.Fatal(srv.ServeTLS(ln, certFile, keyFile))
+		// certFile/keyFile are passed to buildTLSConfig above, which already
+		// set GetCertificate (certReloader) on srv.TLSConfig. Passing "" here
+		// keeps TLSConfig.Certificates empty, so crypto/tls always consults
+		// GetCertificate -- including for non-SNI clients -- instead of
+		// ServeTLS loading the cert once more into Certificates and serving
+		// that stale copy forever to anyone who connects without SNI.
+		serveErr = srv.ServeTLS(ln, "", "")
 	} else {
-		log.Fatal(srv.Serve(ln))
+		serveErr = srv.Serve(ln)
+	}
+
+	if serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+		log.Fatal(serveErr)
 	}
+	// Serve returns as soon as Shutdown starts; wait for the drain of
+	// in-flight requests to finish before exiting.
+	<-shutdownDone
 }
 
 // (Optional helper) If you ever need a distinct TCP port: export PORT and LISTEN_ADDR=":PORT"
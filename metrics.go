@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"pangLB/checks"
+)
+
+var (
+	checkDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "pangb_check_duration_seconds",
+		Help: "Duration of each health check run, labeled by check name.",
+	}, []string{"check"})
+
+	checkSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pangb_check_success",
+		Help: "1 if the most recent run of a check succeeded, 0 otherwise.",
+	}, []string{"check"})
+
+	checkFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pangb_check_failures_total",
+		Help: "Total number of failed runs of each check.",
+	}, []string{"check"})
+
+	healthzRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pangb_healthz_requests_total",
+		Help: "Total /healthz requests, labeled by response status code.",
+	}, []string{"code"})
+)
+
+// promObserver implements checks.Observer, feeding per-check outcomes into
+// the Prometheus collectors above.
+type promObserver struct{}
+
+func (promObserver) Observe(name string, _ bool, detail checks.CheckDetail, duration time.Duration) {
+	checkDuration.WithLabelValues(name).Observe(duration.Seconds())
+	if detail.OK {
+		checkSuccess.WithLabelValues(name).Set(1)
+	} else {
+		checkSuccess.WithLabelValues(name).Set(0)
+		checkFailuresTotal.WithLabelValues(name).Inc()
+	}
+}
+
+// observeHealthzRequest records the status code pangLB answered /healthz
+// with, for the pangb_healthz_requests_total counter.
+func observeHealthzRequest(code int) {
+	healthzRequestsTotal.WithLabelValues(strconv.Itoa(code)).Inc()
+}
+
+// metricsHandler wraps promhttp's default handler with optional bearer
+// token auth via METRICS_TOKEN.
+func metricsHandler() http.Handler {
+	h := promhttp.Handler()
+	token := os.Getenv("METRICS_TOKEN")
+	if token == "" {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !bearerTokenMatches(r, token) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// registerMetrics exposes /metrics on mux, unless METRICS_BIND is set, in
+// which case it serves on its own listener so scraping can be firewalled
+// off separately from the health endpoint. It returns the standalone
+// metrics *http.Server so callers can Shutdown it on reload (it has no
+// fd-passing path of its own, so it must free its port before a SIGHUP
+// re-exec child tries to bind the same address), or nil when METRICS_BIND
+// is unset.
+func registerMetrics(mux *http.ServeMux) *http.Server {
+	bind := getenv("METRICS_BIND", "")
+	if bind == "" {
+		mux.Handle("/metrics", metricsHandler())
+		return nil
+	}
+
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", metricsHandler())
+	srv := &http.Server{
+		Addr:              bind,
+		Handler:           metricsMux,
+		ReadHeaderTimeout: 3 * time.Second,
+	}
+	go func() {
+		log.Printf("metrics server listening on %s", bind)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("metrics server error: %v", err)
+		}
+	}()
+	return srv
+}
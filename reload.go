@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// reexecEnv is set in the environment of a SIGHUP-spawned child so it
+// knows to claim the inherited listener fd (LISTEN_FDS=1) before calling
+// listen().
+const reexecEnv = "PANGLB_REEXEC"
+
+// fileListener is implemented by *net.TCPListener (both a fresh
+// net.Listen result and a socket-activated net.FileListener result), and
+// lets us hand its underlying fd to a re-exec'd child.
+type fileListener interface {
+	File() (*os.File, error)
+}
+
+// maybeAdoptReexecListener sets LISTEN_PID to our own pid when we were
+// started as a SIGHUP re-exec child, so the systemd activation package's
+// pid check passes and it hands back the inherited listener fd.
+func maybeAdoptReexecListener() {
+	if os.Getenv(reexecEnv) == "" {
+		return
+	}
+	_ = os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+}
+
+// reexec forks a child process that inherits ln's fd via ExtraFiles, sets
+// LISTEN_FDS/LISTEN_PID-compatible env for it, then drains srv's in-flight
+// requests in this (parent) process via Shutdown. This gives a
+// zero-downtime reload on SIGHUP: the listening socket is never closed,
+// so no connection attempt is ever refused during the handover.
+//
+// metricsSrv, if non-nil, is the standalone METRICS_BIND server. It has no
+// fd to pass through, so it is shut down here before the child is spawned,
+// freeing its port so the child's own ListenAndServe on METRICS_BIND
+// doesn't race the still-bound parent and fail.
+func reexec(srv *http.Server, ln net.Listener, metricsSrv *http.Server) {
+	fl, ok := ln.(fileListener)
+	if !ok {
+		log.Printf("SIGHUP reload: listener does not support fd passing, ignoring")
+		return
+	}
+	f, err := fl.File()
+	if err != nil {
+		log.Printf("SIGHUP reload: could not get listener fd: %v", err)
+		return
+	}
+	defer f.Close()
+
+	executable, err := os.Executable()
+	if err != nil {
+		log.Printf("SIGHUP reload: could not resolve executable: %v", err)
+		return
+	}
+
+	if metricsSrv != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := metricsSrv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("SIGHUP reload: metrics server shutdown error: %v", err)
+		}
+		cancel()
+	}
+
+	env := append(os.Environ(),
+		fmt.Sprintf("%s=1", reexecEnv),
+		"LISTEN_FDS=1",
+	)
+
+	proc, err := os.StartProcess(executable, os.Args, &os.ProcAttr{
+		Env:   env,
+		Files: []*os.File{os.Stdin, os.Stdout, os.Stderr, f},
+	})
+	if err != nil {
+		log.Printf("SIGHUP reload: failed to spawn child: %v", err)
+		return
+	}
+	log.Printf("SIGHUP reload: spawned child pid %d, draining in-flight requests", proc.Pid)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("SIGHUP reload: shutdown error: %v", err)
+	}
+}
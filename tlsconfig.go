@@ -0,0 +1,140 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// buildTLSConfig assembles the server's tls.Config from TLS_* env vars:
+// minimum version, cipher suite allowlist, hot-reloaded server certificate,
+// and optional mTLS client-certificate verification (e.g. for Cloudflare's
+// Authenticated Origin Pull).
+func buildTLSConfig(certFile, keyFile string) *tls.Config {
+	cfg := &tls.Config{
+		MinVersion:   tlsMinVersion(os.Getenv("TLS_MIN_VERSION")),
+		CipherSuites: parseCipherSuites(os.Getenv("TLS_CIPHER_SUITES")),
+	}
+
+	if certFile != "" && keyFile != "" {
+		cfg.GetCertificate = certReloader(certFile, keyFile)
+	}
+
+	if caFile := os.Getenv("TLS_CLIENT_CA_FILE"); caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			log.Fatalf("load TLS_CLIENT_CA_FILE: %v", err)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return cfg
+}
+
+// certReloader returns a tls.Config.GetCertificate callback that re-reads
+// certFile/keyFile on every handshake, so a renewed certificate from
+// cert-manager/certbot is picked up without a restart.
+func certReloader(certFile, keyFile string) func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load TLS cert/key: %w", err)
+		}
+		return &cert, nil
+	}
+}
+
+// loadCertPool parses a PEM bundle that may contain a chain of
+// certificates (e.g. an intermediate + root CA), decoding each PEM block
+// in turn and adding every CERTIFICATE block to the returned pool. It
+// errors if the file contains no certificates at all.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	pool := x509.NewCertPool()
+	rest := data
+	n := 0
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse certificate in %s: %w", path, err)
+		}
+		pool.AddCert(cert)
+		n++
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// tlsMinVersion maps TLS_MIN_VERSION ("1.0".."1.3") to its crypto/tls
+// constant, defaulting to TLS 1.2 when unset or unrecognized.
+func tlsMinVersion(s string) uint16 {
+	switch strings.TrimSpace(s) {
+	case "1.0":
+		return tls.VersionTLS10
+	case "1.1":
+		return tls.VersionTLS11
+	case "1.3":
+		return tls.VersionTLS13
+	case "", "1.2":
+		return tls.VersionTLS12
+	default:
+		log.Printf("unknown TLS_MIN_VERSION %q, defaulting to 1.2", s)
+		return tls.VersionTLS12
+	}
+}
+
+// cipherSuiteNames maps Go's cipher suite names (as listed by
+// tls.CipherSuites/tls.InsecureCipherSuites) to their IDs, for
+// TLS_CIPHER_SUITES parsing.
+var cipherSuiteNames = func() map[string]uint16 {
+	m := make(map[string]uint16)
+	for _, cs := range tls.CipherSuites() {
+		m[cs.Name] = cs.ID
+	}
+	for _, cs := range tls.InsecureCipherSuites() {
+		m[cs.Name] = cs.ID
+	}
+	return m
+}()
+
+// parseCipherSuites parses a comma-separated TLS_CIPHER_SUITES list of Go
+// cipher suite names into their IDs, skipping (and logging) any name it
+// doesn't recognize. An empty/unset value leaves the default enabled set.
+func parseCipherSuites(s string) []uint16 {
+	if s == "" {
+		return nil
+	}
+	var ids []uint16
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		id, ok := cipherSuiteNames[name]
+		if !ok {
+			log.Printf("unknown TLS_CIPHER_SUITES entry %q, ignoring", name)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
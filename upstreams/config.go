@@ -0,0 +1,93 @@
+package upstreams
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the on-disk shape of UPSTREAMS_CONFIG: a map of backend
+// name to its stanza, plus a pool-wide tuning section.
+type fileConfig struct {
+	Upstreams map[string]backendStanza `yaml:"upstreams" json:"upstreams"`
+	Pool      poolStanza               `yaml:"pool" json:"pool"`
+}
+
+type backendStanza struct {
+	URL    string `yaml:"url" json:"url"`
+	Weight int    `yaml:"weight" json:"weight"`
+}
+
+type poolStanza struct {
+	Interval string `yaml:"interval" json:"interval"`
+	Timeout  string `yaml:"timeout" json:"timeout"`
+	Rise     int    `yaml:"rise" json:"rise"`
+	Fall     int    `yaml:"fall" json:"fall"`
+	MinUp    int    `yaml:"min_up" json:"min_up"`
+}
+
+// LoadConfig reads a goss-style YAML or JSON UPSTREAMS_CONFIG file and
+// returns the resulting Config.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("read upstreams config: %w", err)
+	}
+
+	var fc fileConfig
+	if strings.HasSuffix(path, ".json") {
+		if err := json.Unmarshal(data, &fc); err != nil {
+			return Config{}, fmt.Errorf("parse upstreams config as JSON: %w", err)
+		}
+	} else {
+		if err := yaml.Unmarshal(data, &fc); err != nil {
+			return Config{}, fmt.Errorf("parse upstreams config as YAML: %w", err)
+		}
+	}
+
+	cfg := Config{
+		Interval: parseDuration(fc.Pool.Interval, 0),
+		Timeout:  parseDuration(fc.Pool.Timeout, 0),
+		Rise:     fc.Pool.Rise,
+		Fall:     fc.Pool.Fall,
+		MinUp:    fc.Pool.MinUp,
+	}
+	for name, s := range fc.Upstreams {
+		cfg.Backends = append(cfg.Backends, BackendConfig{Name: name, URL: s.URL, Weight: s.Weight})
+	}
+	return cfg, nil
+}
+
+// ParseEnvBackends parses the compact UPSTREAMS env form:
+// "name=url,name2=url2", for deployments that would rather not ship a
+// config file just to declare a backend pool.
+func ParseEnvBackends(s string) []BackendConfig {
+	var backends []BackendConfig
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, url, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		backends = append(backends, BackendConfig{Name: strings.TrimSpace(name), URL: strings.TrimSpace(url)})
+	}
+	return backends
+}
+
+func parseDuration(s string, def time.Duration) time.Duration {
+	if s == "" {
+		return def
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return def
+	}
+	return d
+}
@@ -0,0 +1,172 @@
+package upstreams
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+
+	"pangLB/checks"
+)
+
+// BackendConfig declares one pool member.
+type BackendConfig struct {
+	Name   string
+	URL    string
+	Weight int
+}
+
+// Config configures a Pool.
+type Config struct {
+	Backends []BackendConfig
+	Interval time.Duration
+	Timeout  time.Duration
+	Rise     int // consecutive successes required to mark a backend up
+	Fall     int // consecutive failures required to mark a backend down
+	MinUp    int // minimum backends that must be up for the pool to be healthy
+}
+
+// Pool periodically probes a set of backends and exposes their aggregate
+// and per-backend health.
+type Pool struct {
+	cfg      Config
+	backends []*Backend
+	client   *http.Client
+}
+
+// NewPool builds a Pool from cfg, applying sane defaults for any zero
+// fields. It does not start probing until Start is called.
+func NewPool(cfg Config) *Pool {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 3 * time.Second
+	}
+	if cfg.Rise <= 0 {
+		cfg.Rise = 2
+	}
+	if cfg.Fall <= 0 {
+		cfg.Fall = 3
+	}
+	if cfg.MinUp <= 0 {
+		cfg.MinUp = 1
+	}
+
+	backends := make([]*Backend, 0, len(cfg.Backends))
+	for _, bc := range cfg.Backends {
+		backends = append(backends, &Backend{Name: bc.Name, URL: bc.URL, Weight: bc.Weight})
+	}
+
+	return &Pool{
+		cfg:      cfg,
+		backends: backends,
+		client: &http.Client{
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS12}},
+		},
+	}
+}
+
+// Start launches one probe loop per backend; it returns immediately and
+// stops when ctx is canceled.
+func (p *Pool) Start(ctx context.Context) {
+	for _, b := range p.backends {
+		go p.probeLoop(ctx, b)
+	}
+}
+
+func (p *Pool) probeLoop(ctx context.Context, b *Backend) {
+	p.probe(ctx, b)
+
+	t := time.NewTicker(p.cfg.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			p.probe(ctx, b)
+		}
+	}
+}
+
+func (p *Pool) probe(ctx context.Context, b *Backend) {
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.URL, nil)
+	if err != nil {
+		b.record(false, 0, "bad request: "+err.Error(), p.cfg.Rise, p.cfg.Fall)
+		return
+	}
+	resp, err := p.client.Do(req)
+	latency := time.Since(start)
+	if err != nil {
+		b.record(false, latency, "request failed: "+err.Error(), p.cfg.Rise, p.cfg.Fall)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b.record(false, latency, fmt.Sprintf("status %d", resp.StatusCode), p.cfg.Rise, p.cfg.Fall)
+		return
+	}
+	b.record(true, latency, fmt.Sprintf("status %d", resp.StatusCode), p.cfg.Rise, p.cfg.Fall)
+}
+
+// Status returns a snapshot of every backend in the pool.
+func (p *Pool) Status() []Status {
+	statuses := make([]Status, 0, len(p.backends))
+	for _, b := range p.backends {
+		statuses = append(statuses, b.snapshot())
+	}
+	return statuses
+}
+
+// Drain administratively marks backend name down for a maintenance
+// window, regardless of probe results, until the process restarts. It
+// returns false if no backend with that name is registered.
+func (p *Pool) Drain(name string) bool {
+	for _, b := range p.backends {
+		if b.Name == name {
+			b.setDrained(true)
+			return true
+		}
+	}
+	return false
+}
+
+// Healthy reports how many backends are currently up (excluding drained
+// ones) against the pool's configured MinUp threshold.
+func (p *Pool) Healthy() (up, total int, ok bool) {
+	total = len(p.backends)
+	for _, b := range p.backends {
+		s := b.snapshot()
+		if s.Up {
+			up++
+		}
+	}
+	return up, total, up >= p.cfg.MinUp
+}
+
+// Check adapts the pool's aggregate health into a checks.Check, so a
+// Registry can fold "at least K of N backends up" into /healthz alongside
+// the other probes.
+func (p *Pool) Check(name string) checks.Check {
+	return &poolCheck{name: name, pool: p}
+}
+
+type poolCheck struct {
+	name string
+	pool *Pool
+}
+
+func (c *poolCheck) Name() string { return c.name }
+
+func (c *poolCheck) Run(_ context.Context) checks.CheckDetail {
+	up, total, ok := c.pool.Healthy()
+	msg := fmt.Sprintf("%d/%d backends up (want >= %d)", up, total, c.pool.cfg.MinUp)
+	return checks.CheckDetail{OK: ok, Message: msg}
+}
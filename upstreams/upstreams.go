@@ -0,0 +1,95 @@
+// Package upstreams turns pangLB from a passive health reporter into an
+// actual load-balancer component: a Pool of backend URLs is probed on an
+// interval, each backend's up/down state is debounced with rise/fall
+// hysteresis, and the aggregate pool health can be folded into /healthz so
+// a Cloudflare monitor reflects real backend availability.
+package upstreams
+
+import (
+	"sync"
+	"time"
+)
+
+// Backend is a single probed upstream and its current state.
+type Backend struct {
+	Name   string
+	URL    string
+	Weight int
+
+	mu                  sync.Mutex
+	up                  bool
+	drained             bool
+	consecutiveSuccess  int
+	consecutiveFailures int
+	lastCheck           time.Time
+	lastLatency         time.Duration
+	lastMessage         string
+}
+
+// Status is a point-in-time, JSON-friendly snapshot of a Backend.
+type Status struct {
+	Name                string    `json:"name"`
+	URL                 string    `json:"url"`
+	Weight              int       `json:"weight,omitempty"`
+	Up                  bool      `json:"up"`
+	Drained             bool      `json:"drained,omitempty"`
+	LastCheck           time.Time `json:"last_check"`
+	LastLatencyMS       int64     `json:"last_latency_ms"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	Message             string    `json:"message,omitempty"`
+}
+
+// snapshot returns b's current state as a Status. Drained backends are
+// always reported down, regardless of the underlying probe state.
+func (b *Backend) snapshot() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return Status{
+		Name:                b.Name,
+		URL:                 b.URL,
+		Weight:              b.Weight,
+		Up:                  b.up && !b.drained,
+		Drained:             b.drained,
+		LastCheck:           b.lastCheck,
+		LastLatencyMS:       b.lastLatency.Milliseconds(),
+		ConsecutiveFailures: b.consecutiveFailures,
+		Message:             b.lastMessage,
+	}
+}
+
+// record applies the outcome of one probe, advancing the rise/fall
+// hysteresis counters and flipping b.up once the relevant threshold is met.
+func (b *Backend) record(success bool, latency time.Duration, message string, rise, fall int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastCheck = time.Now()
+	b.lastLatency = latency
+	b.lastMessage = message
+
+	if success {
+		b.consecutiveFailures = 0
+		b.consecutiveSuccess++
+		if !b.up && b.consecutiveSuccess >= rise {
+			b.up = true
+		}
+	} else {
+		b.consecutiveSuccess = 0
+		b.consecutiveFailures++
+		if b.up && b.consecutiveFailures >= fall {
+			b.up = false
+		}
+	}
+}
+
+func (b *Backend) setDrained(drained bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.drained = drained
+}
+
+func (b *Backend) isDrained() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.drained
+}
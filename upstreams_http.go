@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"pangLB/upstreams"
+)
+
+func parseIntEnv(k string, d int) int {
+	v := os.Getenv(k)
+	if v == "" {
+		return d
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return d
+	}
+	return n
+}
+
+// buildPool loads a backend pool from UPSTREAMS_CONFIG (goss-style
+// YAML/JSON) or the compact UPSTREAMS env form ("name=url,name2=url2"). It
+// returns nil if neither is set, meaning pangLB is running as a passive
+// health reporter only.
+func buildPool() *upstreams.Pool {
+	if cfgPath := os.Getenv("UPSTREAMS_CONFIG"); cfgPath != "" {
+		cfg, err := upstreams.LoadConfig(cfgPath)
+		if err != nil {
+			log.Fatalf("load UPSTREAMS_CONFIG: %v", err)
+		}
+		return upstreams.NewPool(cfg)
+	}
+
+	list := os.Getenv("UPSTREAMS")
+	if list == "" {
+		return nil
+	}
+
+	cfg := upstreams.Config{
+		Backends: upstreams.ParseEnvBackends(list),
+		Interval: parseDurationEnv("UPSTREAMS_INTERVAL", 0),
+		Timeout:  parseDurationEnv("UPSTREAMS_TIMEOUT", 0),
+		Rise:     parseIntEnv("UPSTREAMS_RISE", 0),
+		Fall:     parseIntEnv("UPSTREAMS_FALL", 0),
+		MinUp:    parseIntEnv("UPSTREAMS_MIN_UP", 0),
+	}
+	return upstreams.NewPool(cfg)
+}
+
+// upstreamsHandler serves GET /upstreams with the per-backend status of
+// every pool member.
+func upstreamsHandler(pool *upstreams.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(pool.Status())
+	}
+}
+
+// upstreamsDrainHandler serves POST /upstreams/{name}/drain, marking the
+// named backend down for a maintenance window. This is a mutating,
+// administrative action, so it is gated behind DRAIN_TOKEN bearer auth
+// (same pattern as METRICS_TOKEN) whenever that env var is set.
+func upstreamsDrainHandler(pool *upstreams.Pool) http.HandlerFunc {
+	token := os.Getenv("DRAIN_TOKEN")
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && !bearerTokenMatches(r, token) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		rest := strings.TrimPrefix(r.URL.Path, "/upstreams/")
+		name, ok := strings.CutSuffix(rest, "/drain")
+		if !ok || name == "" || !pool.Drain(name) {
+			http.Error(w, "unknown backend", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"name": name, "status": "drained"})
+	}
+}
+
+// startPool launches pool's probe loops, tied to ctx's lifetime.
+func startPool(ctx context.Context, pool *upstreams.Pool) {
+	pool.Start(ctx)
+}